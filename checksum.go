@@ -0,0 +1,95 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+
+	digest "github.com/opencontainers/go-digest"
+)
+
+// Checksum computes a stable content-addressable digest of vendorDir,
+// modeled on buildkit's contenthash package: the tree is walked in sorted
+// order (filepath.Walk's default), and each entry's relative POSIX path,
+// mode bits, symlink target (if any), and content digest (for regular
+// files) are hashed into a per-entry record, which is folded into one
+// running hash. Directory entries contribute two records — a header and a
+// contents marker — so an empty directory hashes differently from a
+// missing one. The result is deterministic across OSes and independent of
+// the order entries were created on disk.
+func Checksum(vendorDir string) (digest.Digest, error) {
+	h := sha256.New()
+
+	err := filepath.Walk(vendorDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == vendorDir {
+			return nil
+		}
+
+		rel, err := filepath.Rel(vendorDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		mode := info.Mode()
+
+		switch {
+		case mode&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			hashRecord(h, rel, "symlink", target, nil)
+
+		case info.IsDir():
+			hashRecord(h, rel, fmt.Sprintf("dir-header:%04o", mode.Perm()), "", nil)
+			hashRecord(h, rel, "dir-contents", "", nil)
+
+		default:
+			sum, err := sha256File(path)
+			if err != nil {
+				return err
+			}
+			hashRecord(h, rel, fmt.Sprintf("file:%04o", mode.Perm()), "", sum)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return digest.NewDigest(digest.SHA256, h), nil
+}
+
+// hashRecord hashes a single canonical entry record and folds the result
+// into h, so the overall digest is a hash of fixed-size record digests
+// rather than of the raw record bytes. The fold depends on the order of
+// calls, which Checksum keeps stable by walking in sorted order.
+func hashRecord(h hash.Hash, path, kind, target string, contentSum []byte) {
+	record := sha256.New()
+	fmt.Fprintf(record, "path=%s\nkind=%s\ntarget=%s\n", path, kind, target)
+	if contentSum != nil {
+		record.Write(contentSum)
+	}
+	h.Write(record.Sum(nil))
+}
+
+// sha256File returns the SHA-256 digest of a regular file's contents.
+func sha256File(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// matchGlob reports whether path (a slash-separated path relative to a
+// vendored package root, e.g. "testdata/fixture.json") matches a
+// doublestar-style glob pattern such as "**/*.proto", "**/testdata/**", or
+// "cmd/**". A "**" segment matches zero or more path segments; any other
+// segment is matched against the corresponding path segment with
+// filepath.Match, so "*" and "?" work within a single segment but never
+// cross a "/".
+func matchGlob(pattern, path string) bool {
+	return matchGlobSegments(strings.Split(pattern, "/"), strings.Split(path, "/"))
+}
+
+func matchGlobSegments(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+
+	if pattern[0] == "**" {
+		if len(pattern) == 1 {
+			return true
+		}
+		for i := 0; i <= len(path); i++ {
+			if matchGlobSegments(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(path) == 0 {
+		return false
+	}
+	ok, err := filepath.Match(pattern[0], path[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchGlobSegments(pattern[1:], path[1:])
+}
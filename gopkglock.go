@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+// gopkgLock mirrors the subset of dep's Gopkg.lock that cleanup needs.
+type gopkgLock struct {
+	Projects []gopkgProject `toml:"projects"`
+}
+
+// gopkgProject is a single [[projects]] entry from Gopkg.lock. Packages
+// lists the subpackages dep resolved for this project, relative to Name;
+// the project root itself is recorded as ".".
+type gopkgProject struct {
+	Name     string   `toml:"name"`
+	Packages []string `toml:"packages"`
+	Revision string   `toml:"revision"`
+}
+
+// Packages implements LockReader.
+func (l gopkgLock) Packages() []PackageSpec {
+	var specs []PackageSpec
+	for _, p := range l.Projects {
+		spec := PackageSpec{ImportPath: p.Name}
+		for _, pkg := range p.Packages {
+			if pkg == "" || pkg == "." {
+				continue
+			}
+			spec.Subpackages = append(spec.Subpackages, pkg)
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// readGopkgLock reads and parses dir's Gopkg.lock.
+func readGopkgLock(dir string) (gopkgLock, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "Gopkg.lock"))
+	if err != nil {
+		return gopkgLock{}, fmt.Errorf("failed to read Gopkg.lock: %v", err)
+	}
+
+	var lock gopkgLock
+	if _, err := toml.Decode(string(data), &lock); err != nil {
+		return gopkgLock{}, fmt.Errorf("failed to parse Gopkg.lock: %v", err)
+	}
+	return lock, nil
+}
@@ -0,0 +1,206 @@
+// Command glide-vc ("vendor cleaner") prunes files from a glide-managed
+// vendor/ tree that aren't needed to build the project, such as tests,
+// non-Go sources, and nested vendor directories left over from upstream
+// repos that vendor their own dependencies.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// options controls which files cleanup removes from vendor/.
+type options struct {
+	onlyGo  bool // keep only .go files
+	noTests bool // remove _test.go files
+
+	keep    []string // glob patterns to always retain, relative to each package root
+	exclude []string // glob patterns to always remove, relative to each package root
+
+	// lockFormat forces which dependency lock file to read: "glide", "dep",
+	// or "mod". "" or "auto" detects whichever one is present.
+	lockFormat string
+}
+
+// globListFlag implements flag.Value for repeatable glob-pattern flags
+// like -keep and -exclude.
+type globListFlag []string
+
+func (f *globListFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *globListFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func main() {
+	var opts options
+	var dryRun bool
+	var format string
+	var printHash bool
+	flag.BoolVar(&opts.onlyGo, "only-code", false, "keep only files needed to build the code (*.go)")
+	flag.BoolVar(&opts.noTests, "no-tests", false, "remove _test.go files")
+	flag.Var((*globListFlag)(&opts.keep), "keep", "glob pattern (relative to each vendored package root) to retain even if it would otherwise be pruned; repeatable")
+	flag.Var((*globListFlag)(&opts.exclude), "exclude", "glob pattern to remove even if it would otherwise be kept; repeatable")
+	flag.StringVar(&opts.lockFormat, "lock-format", "auto", "dependency lock file to read: auto, glide, dep, or mod")
+	flag.BoolVar(&dryRun, "dry-run", false, "report what cleanup would remove without touching disk (same as the verify subcommand)")
+	flag.StringVar(&format, "format", "text", "output format for verify: text or json")
+	flag.BoolVar(&printHash, "print-hash", false, "print the vendor/ content digest after cleanup")
+	flag.Parse()
+
+	mode := "cleanup"
+	if args := flag.Args(); len(args) > 0 {
+		mode = args[0]
+	}
+	if dryRun {
+		mode = "verify"
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "glide-vc: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch mode {
+	case "cleanup":
+		if err := cleanup(dir, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "glide-vc: %v\n", err)
+			os.Exit(1)
+		}
+		if printHash {
+			sum, err := Checksum(filepath.Join(dir, "vendor"))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "glide-vc: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println(sum)
+		}
+	case "verify":
+		diff, err := verify(dir, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "glide-vc: %v\n", err)
+			os.Exit(1)
+		}
+		if err := printDiff(os.Stdout, diff, format); err != nil {
+			fmt.Fprintf(os.Stderr, "glide-vc: %v\n", err)
+			os.Exit(1)
+		}
+		if len(diff.Removed) > 0 || len(diff.Added) > 0 {
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "glide-vc: unknown mode %q\n", mode)
+		os.Exit(2)
+	}
+}
+
+// glideLock mirrors the subset of glide.lock that cleanup needs.
+type glideLock struct {
+	Imports []lockedPackage `yaml:"imports"`
+}
+
+// lockedPackage is a single entry from glide.lock's imports list.
+type lockedPackage struct {
+	Name        string   `yaml:"name"`
+	Version     string   `yaml:"version"`
+	Subpackages []string `yaml:"subpackages"`
+}
+
+// Packages implements LockReader.
+func (l glideLock) Packages() []PackageSpec {
+	var specs []PackageSpec
+	for _, imp := range l.Imports {
+		specs = append(specs, PackageSpec{ImportPath: imp.Name, Subpackages: imp.Subpackages})
+	}
+	return specs
+}
+
+// cleanup prunes vendor/ under dir according to opts and any per-project
+// overrides in glide.yaml's prune stanza, using the package list recorded
+// in the dependency lock file (glide.lock, Gopkg.lock, or
+// vendor/modules.txt, per opts.lockFormat). It computes the desired state and applies the diff
+// against what's currently on disk, the same way verify computes it
+// without applying it.
+func cleanup(dir string, opts options) error {
+	plan, err := computePrunePlan(dir, opts)
+	if err != nil {
+		return err
+	}
+	return applyPrunePlan(plan)
+}
+
+// shouldRemove decides whether a regular file should be pruned from the
+// vendor tree given the effective PruneOptions for its project. rel is the
+// file's path relative to the package root, used to evaluate Keep/Exclude
+// globs; info is used for the filename-based rules.
+func shouldRemove(rel string, info os.FileInfo, popts PruneOptions) bool {
+	for _, pattern := range popts.Exclude {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	for _, pattern := range popts.Keep {
+		if matchGlob(pattern, rel) {
+			return false
+		}
+	}
+
+	name := info.Name()
+	isGo := strings.HasSuffix(name, ".go")
+	isTest := strings.HasSuffix(name, "_test.go")
+
+	if popts.GoTests && isTest {
+		return true
+	}
+	if isLicenseFile(name) {
+		return popts.NonLicenseFiles
+	}
+	if popts.NonGoFiles && !isGo {
+		return true
+	}
+	return false
+}
+
+// removeEmptyDirs walks root and removes any directory left empty by
+// applyPrunePlan's deletions, without removing root itself.
+func removeEmptyDirs(root string) error {
+	var dirs []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Process deepest directories first so parents become eligible once
+	// their children have been removed.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		dir := dirs[i]
+		if dir == root {
+			continue
+		}
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			if err := os.Remove(dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
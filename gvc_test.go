@@ -9,22 +9,29 @@ import (
 )
 
 type FileInfo struct {
-	path  string
-	isDir bool
+	path    string
+	isDir   bool
+	symlink bool   // path is a symlink rather than a regular file/dir
+	target  string // symlink target, as passed to os.Symlink, when symlink is true
 }
 
 func createVendorTree(t *testing.T, dir string, tree []FileInfo) error {
 	for _, fi := range tree {
 		path := filepath.Join(dir, "vendor", fi.path)
-		if fi.isDir {
-			if err := os.MkdirAll(path, 0777); err != nil {
-				return fmt.Errorf("failed to create dir %q: %v", filepath.Dir(path), err)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			return fmt.Errorf("failed to create dir %q: %v", filepath.Dir(path), err)
+		}
+
+		switch {
+		case fi.symlink:
+			if err := os.Symlink(fi.target, path); err != nil {
+				return fmt.Errorf("failed to create symlink %q: %v", path, err)
 			}
-		} else {
-			// Create parent dir
-			if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		case fi.isDir:
+			if err := os.MkdirAll(path, 0777); err != nil {
 				return fmt.Errorf("failed to create dir %q: %v", filepath.Dir(path), err)
 			}
+		default:
 			f, err := os.Create(path)
 			if err != nil {
 				return fmt.Errorf("failed to create file %q: %v", path, err)
@@ -45,10 +52,7 @@ func checkExpectedVendor(t *testing.T, dir string, exp []FileInfo) error {
 		}
 		for _, fi := range exp {
 			if filepath.Join(dir, "vendor", fi.path) == path {
-				if fi.isDir != info.IsDir() {
-					return fmt.Errorf("mismatching type for %s, expected dir: %t, got dir: %t", fi.path, fi.isDir, info.IsDir())
-				}
-				return nil
+				return checkFileInfoType(fi, info)
 			}
 		}
 		return fmt.Errorf("file %s shouldn't exist", path)
@@ -56,35 +60,65 @@ func checkExpectedVendor(t *testing.T, dir string, exp []FileInfo) error {
 
 	// Check that all files in exp exists in vendor dir
 	for _, fi := range exp {
-		vfi, err := os.Stat(filepath.Join(vendorPath, fi.path))
+		vfi, err := os.Lstat(filepath.Join(vendorPath, fi.path))
 		if err != nil {
 			return fmt.Errorf("error searching for file %s: %v", fi.path, err)
 		}
-		if fi.isDir != vfi.IsDir() {
-			return fmt.Errorf("mismatching type for %s, expected dir: %t, got dir: %t", fi.path, fi.isDir, vfi.IsDir())
+		if err := checkFileInfoType(fi, vfi); err != nil {
+			return err
 		}
 	}
 	return err
 }
 
+func checkFileInfoType(fi FileInfo, info os.FileInfo) error {
+	isSymlink := info.Mode()&os.ModeSymlink != 0
+	if fi.symlink != isSymlink {
+		return fmt.Errorf("mismatching symlink-ness for %s, expected symlink: %t, got symlink: %t", fi.path, fi.symlink, isSymlink)
+	}
+	if !isSymlink && fi.isDir != info.IsDir() {
+		return fmt.Errorf("mismatching type for %s, expected dir: %t, got dir: %t", fi.path, fi.isDir, info.IsDir())
+	}
+	return nil
+}
+
 type testData struct {
 	tree          []FileInfo
 	lockdata      string
 	expectedFiles []FileInfo
 	opts          options
+
+	// lockFormat picks which lock file lockdata is written as: "" (or
+	// "glide") for glide.lock, "dep" for Gopkg.lock, "mod" for
+	// vendor/modules.txt. It's also set on opts.lockFormat, so tests cover
+	// both the fixture and the -lock-format selection in one go.
+	lockFormat string
+}
+
+// lockFileName returns the lock file testCleanup should write lockdata to
+// for the given -lock-format value.
+func lockFileName(format string) string {
+	switch format {
+	case "dep":
+		return "Gopkg.lock"
+	case "mod":
+		return filepath.Join("vendor", "modules.txt")
+	default:
+		return "glide.lock"
+	}
 }
 
 func TestCleanup(t *testing.T) {
 
 	tree := []FileInfo{
-		{"host01/org01/repo01/README", false},
-		{"host01/org01/repo01/LICENSE", false},
-		{"host01/org01/repo01/file01.go", false},
-		{"host01/org01/repo01/file01_test.go", false},
-		{"host01/org01/repo01/subpkg01/file02.go", false},
-		{"host01/org01/repo01/subpkg01/file02_test.go", false},
-		{"host01/org01/repo01/vendor/host01/org02/repo01/file03.go", false},
-		{"host01/org01/repo01/vendor/host01/org02/repo01/file03_test.go", false},
+		{path: "host01/org01/repo01/README", isDir: false},
+		{path: "host01/org01/repo01/LICENSE", isDir: false},
+		{path: "host01/org01/repo01/file01.go", isDir: false},
+		{path: "host01/org01/repo01/file01_test.go", isDir: false},
+		{path: "host01/org01/repo01/subpkg01/file02.go", isDir: false},
+		{path: "host01/org01/repo01/subpkg01/file02_test.go", isDir: false},
+		{path: "host01/org01/repo01/vendor/host01/org02/repo01/file03.go", isDir: false},
+		{path: "host01/org01/repo01/vendor/host01/org02/repo01/file03_test.go", isDir: false},
 	}
 
 	lockdata := `
@@ -103,12 +137,12 @@ devImports: []
 			tree:     tree,
 			lockdata: lockdata,
 			expectedFiles: []FileInfo{
-				{"host01", true},
-				{"host01/org01", true},
-				{"host01/org01/repo01", true},
-				{"host01/org01/repo01/file01.go", false},
-				{"host01/org01/repo01/subpkg01", true},
-				{"host01/org01/repo01/subpkg01/file02.go", false},
+				{path: "host01", isDir: true},
+				{path: "host01/org01", isDir: true},
+				{path: "host01/org01/repo01", isDir: true},
+				{path: "host01/org01/repo01/file01.go", isDir: false},
+				{path: "host01/org01/repo01/subpkg01", isDir: true},
+				{path: "host01/org01/repo01/subpkg01/file02.go", isDir: false},
 			},
 			opts: options{onlyGo: true, noTests: true},
 		},
@@ -116,14 +150,14 @@ devImports: []
 			tree:     tree,
 			lockdata: lockdata,
 			expectedFiles: []FileInfo{
-				{"host01", true},
-				{"host01/org01", true},
-				{"host01/org01/repo01", true},
-				{"host01/org01/repo01/file01.go", false},
-				{"host01/org01/repo01/file01_test.go", false},
-				{"host01/org01/repo01/subpkg01", true},
-				{"host01/org01/repo01/subpkg01/file02.go", false},
-				{"host01/org01/repo01/subpkg01/file02_test.go", false},
+				{path: "host01", isDir: true},
+				{path: "host01/org01", isDir: true},
+				{path: "host01/org01/repo01", isDir: true},
+				{path: "host01/org01/repo01/file01.go", isDir: false},
+				{path: "host01/org01/repo01/file01_test.go", isDir: false},
+				{path: "host01/org01/repo01/subpkg01", isDir: true},
+				{path: "host01/org01/repo01/subpkg01/file02.go", isDir: false},
+				{path: "host01/org01/repo01/subpkg01/file02_test.go", isDir: false},
 			},
 			opts: options{onlyGo: true},
 		},
@@ -131,16 +165,16 @@ devImports: []
 			tree:     tree,
 			lockdata: lockdata,
 			expectedFiles: []FileInfo{
-				{"host01", true},
-				{"host01/org01", true},
-				{"host01/org01/repo01", true},
-				{"host01/org01/repo01/README", false},
-				{"host01/org01/repo01/LICENSE", false},
-				{"host01/org01/repo01/file01.go", false},
-				{"host01/org01/repo01/file01_test.go", false},
-				{"host01/org01/repo01/subpkg01", true},
-				{"host01/org01/repo01/subpkg01/file02.go", false},
-				{"host01/org01/repo01/subpkg01/file02_test.go", false},
+				{path: "host01", isDir: true},
+				{path: "host01/org01", isDir: true},
+				{path: "host01/org01/repo01", isDir: true},
+				{path: "host01/org01/repo01/README", isDir: false},
+				{path: "host01/org01/repo01/LICENSE", isDir: false},
+				{path: "host01/org01/repo01/file01.go", isDir: false},
+				{path: "host01/org01/repo01/file01_test.go", isDir: false},
+				{path: "host01/org01/repo01/subpkg01", isDir: true},
+				{path: "host01/org01/repo01/subpkg01/file02.go", isDir: false},
+				{path: "host01/org01/repo01/subpkg01/file02_test.go", isDir: false},
 			},
 		},
 	}
@@ -153,7 +187,190 @@ devImports: []
 	}
 }
 
+// TestCleanupPruneOverrides exercises the glide.yaml `prune:` stanza: a
+// project-wide default applies to every vendored package, but a
+// per-project override can contradict it for one import path.
+func TestCleanupPruneOverrides(t *testing.T) {
+	tree := []FileInfo{
+		{path: "host01/org01/repo01/file01.go", isDir: false},
+		{path: "host01/org01/repo01/file01_test.go", isDir: false},
+		{path: "host02/org01/repo02/file02.go", isDir: false},
+		{path: "host02/org01/repo02/file02_test.go", isDir: false},
+	}
+
+	lockdata := `
+imports:
+- name: host01/org01/repo01
+  version: 76626ae9c91c4f2a10f34cad8ce83ea42c93bb75
+- name: host02/org01/repo02
+  version: 76626ae9c91c4f2a10f34cad8ce83ea42c93bb75
+devImports: []
+`
+
+	glideYaml := `
+prune:
+  default:
+    go-tests: true
+  projects:
+  - name: host01/org01/repo01
+    go-tests: false
+`
+
+	td := testData{
+		tree:     tree,
+		lockdata: lockdata,
+		expectedFiles: []FileInfo{
+			{path: "host01", isDir: true},
+			{path: "host01/org01", isDir: true},
+			{path: "host01/org01/repo01", isDir: true},
+			{path: "host01/org01/repo01/file01.go", isDir: false},
+			{path: "host01/org01/repo01/file01_test.go", isDir: false},
+			{path: "host02", isDir: true},
+			{path: "host02/org01", isDir: true},
+			{path: "host02/org01/repo02", isDir: true},
+			{path: "host02/org01/repo02/file02.go", isDir: false},
+		},
+	}
+
+	if err := testCleanupWithGlideYAML(t, &td, glideYaml); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestCleanupUnusedPackages checks that unused-packages combines with
+// another removal rule (go-tests) without cleanup choking on files it
+// already queued for removal via the unused subpackage's whole-directory
+// delete.
+func TestCleanupUnusedPackages(t *testing.T) {
+	tree := []FileInfo{
+		{path: "host01/org01/repo01/file01.go", isDir: false},
+		{path: "host01/org01/repo01/file01_test.go", isDir: false},
+		{path: "host01/org01/repo01/unused/file02.go", isDir: false},
+		{path: "host01/org01/repo01/unused/file02_test.go", isDir: false},
+	}
+
+	lockdata := `
+imports:
+- name: host01/org01/repo01
+  version: 76626ae9c91c4f2a10f34cad8ce83ea42c93bb75
+devImports: []
+`
+
+	glideYaml := `
+prune:
+  default:
+    unused-packages: true
+    go-tests: true
+`
+
+	td := testData{
+		tree:     tree,
+		lockdata: lockdata,
+		expectedFiles: []FileInfo{
+			{path: "host01", isDir: true},
+			{path: "host01/org01", isDir: true},
+			{path: "host01/org01/repo01", isDir: true},
+			{path: "host01/org01/repo01/file01.go", isDir: false},
+		},
+	}
+
+	if err := testCleanupWithGlideYAML(t, &td, glideYaml); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestVerify checks that verify reports the same removals cleanup would
+// make, without touching disk.
+func TestVerify(t *testing.T) {
+	tree := []FileInfo{
+		{path: "host01/org01/repo01/README", isDir: false},
+		{path: "host01/org01/repo01/file01.go", isDir: false},
+		{path: "host01/org01/repo01/file01_test.go", isDir: false},
+	}
+
+	lockdata := `
+imports:
+- name: host01/org01/repo01
+  version: 76626ae9c91c4f2a10f34cad8ce83ea42c93bb75
+devImports: []
+`
+
+	tmpDir, err := ioutil.TempDir("", "glidevc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatalf("could not change to dir %s: %v", tmpDir, err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(tmpDir, "glide.yaml"), nil, 0666); err != nil {
+		t.Fatalf("failed to create glide.yaml file: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(tmpDir, "glide.lock"), []byte(lockdata), 0666); err != nil {
+		t.Fatalf("failed to create glide.lock file: %v", err)
+	}
+	if err := createVendorTree(t, tmpDir, tree); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := options{onlyGo: true, noTests: true}
+	diff, err := verify(tmpDir, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantRemoved := map[string]bool{
+		"host01/org01/repo01/README":        true,
+		"host01/org01/repo01/file01_test.go": true,
+	}
+	if len(diff.Removed) != len(wantRemoved) {
+		t.Fatalf("unexpected removed set: %v", diff.Removed)
+	}
+	for _, p := range diff.Removed {
+		if !wantRemoved[p] {
+			t.Errorf("unexpected removed path %s", p)
+		}
+	}
+	if len(diff.Added) != 0 {
+		t.Errorf("expected no added paths, got %v", diff.Added)
+	}
+
+	// verify must not touch disk.
+	unchanged := []FileInfo{
+		{path: "host01", isDir: true},
+		{path: "host01/org01", isDir: true},
+		{path: "host01/org01/repo01", isDir: true},
+		{path: "host01/org01/repo01/README", isDir: false},
+		{path: "host01/org01/repo01/file01.go", isDir: false},
+		{path: "host01/org01/repo01/file01_test.go", isDir: false},
+	}
+	if err := checkExpectedVendor(t, tmpDir, unchanged); err != nil {
+		t.Fatalf("verify modified the vendor tree: %v", err)
+	}
+
+	if err := cleanup(tmpDir, opts); err != nil {
+		t.Fatalf("cleanup: %v", err)
+	}
+	clean, err := verify(tmpDir, opts)
+	if err != nil {
+		t.Fatalf("verify after cleanup: %v", err)
+	}
+	if len(clean.Removed) != 0 || len(clean.Added) != 0 {
+		t.Errorf("expected no drift after cleanup, got %+v", clean)
+	}
+}
+
 func testCleanup(t *testing.T, td *testData) error {
+	return testCleanupWithGlideYAML(t, td, "")
+}
+
+// testCleanupWithGlideYAML is like testCleanup but writes glideYaml as the
+// contents of glide.yaml, so tests can exercise the `prune:` stanza.
+func testCleanupWithGlideYAML(t *testing.T, td *testData, glideYaml string) error {
 	tmpDir, err := ioutil.TempDir("", "glidevc")
 	if err != nil {
 		return err
@@ -166,21 +383,22 @@ func testCleanup(t *testing.T, td *testData) error {
 		return fmt.Errorf("Could not change to dir %s: %v", wd, err)
 	}
 
-	// Create empty glide.yaml (currently not used for hash checking)
-	if err := ioutil.WriteFile(filepath.Join(tmpDir, "glide.yaml"), nil, 0666); err != nil {
+	if err := ioutil.WriteFile(filepath.Join(tmpDir, "glide.yaml"), []byte(glideYaml), 0666); err != nil {
 		return fmt.Errorf("failed to create glide.yaml file: %v", err)
 	}
 
-	// Create glide.lock file
-	if err := ioutil.WriteFile(filepath.Join(tmpDir, "glide.lock"), []byte(td.lockdata), 0666); err != nil {
-		return fmt.Errorf("failed to create glide.lock file: %v", err)
-	}
-
 	if err := createVendorTree(t, tmpDir, td.tree); err != nil {
 		return err
 	}
 
-	if err := cleanup(tmpDir, td.opts); err != nil {
+	lockPath := filepath.Join(tmpDir, lockFileName(td.lockFormat))
+	if err := ioutil.WriteFile(lockPath, []byte(td.lockdata), 0666); err != nil {
+		return fmt.Errorf("failed to create %s file: %v", lockPath, err)
+	}
+
+	opts := td.opts
+	opts.lockFormat = td.lockFormat
+	if err := cleanup(tmpDir, opts); err != nil {
 		return err
 	}
 
@@ -189,3 +407,281 @@ func testCleanup(t *testing.T, td *testData) error {
 	}
 	return nil
 }
+
+// TestChecksum checks that Checksum is independent of the order entries
+// were created on disk but sensitive to content and permission changes.
+func TestChecksum(t *testing.T) {
+	dirA, err := ioutil.TempDir("", "glidevc-checksum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirA)
+
+	dirB, err := ioutil.TempDir("", "glidevc-checksum")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirB)
+
+	writeChecksumTree(t, dirA, []string{"a/one.go", "a/two.go", "b/three.go"})
+	writeChecksumTree(t, dirB, []string{"b/three.go", "a/two.go", "a/one.go"})
+
+	sumA, err := Checksum(dirA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sumB, err := Checksum(dirB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sumA != sumB {
+		t.Errorf("expected identical digests regardless of creation order, got %s != %s", sumA, sumB)
+	}
+
+	onePath := filepath.Join(dirB, "a", "one.go")
+	if err := ioutil.WriteFile(onePath, []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sumChanged, err := Checksum(dirB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sumChanged == sumB {
+		t.Errorf("expected digest to change after editing file content")
+	}
+
+	if err := os.Chmod(onePath, 0755); err != nil {
+		t.Fatal(err)
+	}
+	sumChmod, err := Checksum(dirB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sumChmod == sumChanged {
+		t.Errorf("expected digest to change after chmod")
+	}
+}
+
+func writeChecksumTree(t *testing.T, dir string, files []string) {
+	for _, f := range files {
+		path := filepath.Join(dir, f)
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(f), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestCleanupGlobs covers -keep/-exclude interacting with onlyGo and
+// noTests, via CLI-supplied patterns.
+func TestCleanupGlobs(t *testing.T) {
+	tree := []FileInfo{
+		{path: "host01/org01/repo01/README.md", isDir: false},
+		{path: "host01/org01/repo01/file01.go", isDir: false},
+		{path: "host01/org01/repo01/file01_test.go", isDir: false},
+		{path: "host01/org01/repo01/extra.txt", isDir: false},
+	}
+
+	lockdata := `
+imports:
+- name: host01/org01/repo01
+  version: 76626ae9c91c4f2a10f34cad8ce83ea42c93bb75
+devImports: []
+`
+
+	tests := []testData{
+		{
+			// keep overrides onlyGo for *.md, but extra.txt still goes.
+			tree:     tree,
+			lockdata: lockdata,
+			opts:     options{onlyGo: true, keep: []string{"**/*.md"}},
+			expectedFiles: []FileInfo{
+				{path: "host01", isDir: true},
+				{path: "host01/org01", isDir: true},
+				{path: "host01/org01/repo01", isDir: true},
+				{path: "host01/org01/repo01/README.md", isDir: false},
+				{path: "host01/org01/repo01/file01.go", isDir: false},
+				{path: "host01/org01/repo01/file01_test.go", isDir: false},
+			},
+		},
+		{
+			// exclude removes file01.go even with no other options set.
+			tree:     tree,
+			lockdata: lockdata,
+			opts:     options{exclude: []string{"file01.go"}},
+			expectedFiles: []FileInfo{
+				{path: "host01", isDir: true},
+				{path: "host01/org01", isDir: true},
+				{path: "host01/org01/repo01", isDir: true},
+				{path: "host01/org01/repo01/README.md", isDir: false},
+				{path: "host01/org01/repo01/file01_test.go", isDir: false},
+				{path: "host01/org01/repo01/extra.txt", isDir: false},
+			},
+		},
+		{
+			// noTests + exclude on the remaining test data file.
+			tree:     tree,
+			lockdata: lockdata,
+			opts:     options{noTests: true, exclude: []string{"extra.txt"}},
+			expectedFiles: []FileInfo{
+				{path: "host01", isDir: true},
+				{path: "host01/org01", isDir: true},
+				{path: "host01/org01/repo01", isDir: true},
+				{path: "host01/org01/repo01/README.md", isDir: false},
+				{path: "host01/org01/repo01/file01.go", isDir: false},
+			},
+		},
+	}
+
+	for i, td := range tests {
+		t.Logf("Test #%d", i)
+		if err := testCleanup(t, &td); err != nil {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+	}
+}
+
+// TestCleanupGlobsPerProject checks that keep/exclude globs declared in
+// glide.yaml's prune stanza apply per-project, same as the bool mask.
+func TestCleanupGlobsPerProject(t *testing.T) {
+	tree := []FileInfo{
+		{path: "host01/org01/repo01/testdata/fixture.json", isDir: false},
+		{path: "host01/org01/repo01/file01.go", isDir: false},
+		{path: "host02/org01/repo02/testdata/fixture.json", isDir: false},
+		{path: "host02/org01/repo02/file02.go", isDir: false},
+	}
+
+	lockdata := `
+imports:
+- name: host01/org01/repo01
+  version: 76626ae9c91c4f2a10f34cad8ce83ea42c93bb75
+- name: host02/org01/repo02
+  version: 76626ae9c91c4f2a10f34cad8ce83ea42c93bb75
+devImports: []
+`
+
+	glideYaml := `
+prune:
+  default:
+    keep:
+    - "**/testdata/**"
+  projects:
+  - name: host02/org01/repo02
+    exclude:
+    - "**/testdata/**"
+`
+
+	td := testData{
+		tree:     tree,
+		lockdata: lockdata,
+		opts:     options{onlyGo: true},
+		expectedFiles: []FileInfo{
+			{path: "host01", isDir: true},
+			{path: "host01/org01", isDir: true},
+			{path: "host01/org01/repo01", isDir: true},
+			{path: "host01/org01/repo01/testdata", isDir: true},
+			{path: "host01/org01/repo01/testdata/fixture.json", isDir: false},
+			{path: "host01/org01/repo01/file01.go", isDir: false},
+			{path: "host02", isDir: true},
+			{path: "host02/org01", isDir: true},
+			{path: "host02/org01/repo02", isDir: true},
+			{path: "host02/org01/repo02/file02.go", isDir: false},
+		},
+	}
+
+	if err := testCleanupWithGlideYAML(t, &td, glideYaml); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestCleanupSymlinks covers symlinks inside a vendored package: one whose
+// target survives cleanup stays in place, and one whose target is itself
+// pruned (here, a _test.go file removed by noTests) becomes dangling and is
+// pruned along with it.
+func TestCleanupSymlinks(t *testing.T) {
+	tree := []FileInfo{
+		{path: "host01/org01/repo01/file01.go", isDir: false},
+		{path: "host01/org01/repo01/file01_test.go", isDir: false},
+		{path: "host01/org01/repo01/link_to_go", symlink: true, target: "file01.go"},
+		{path: "host01/org01/repo01/link_to_test", symlink: true, target: "file01_test.go"},
+	}
+
+	lockdata := `
+imports:
+- name: host01/org01/repo01
+  version: 76626ae9c91c4f2a10f34cad8ce83ea42c93bb75
+devImports: []
+`
+
+	td := testData{
+		tree:     tree,
+		lockdata: lockdata,
+		opts:     options{noTests: true},
+		expectedFiles: []FileInfo{
+			{path: "host01", isDir: true},
+			{path: "host01/org01", isDir: true},
+			{path: "host01/org01/repo01", isDir: true},
+			{path: "host01/org01/repo01/file01.go", isDir: false},
+			{path: "host01/org01/repo01/link_to_go", symlink: true, target: "file01.go"},
+		},
+	}
+
+	if err := testCleanup(t, &td); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestCleanupLockFormats checks that cleanup prunes the same way regardless
+// of which dependency lock file declares the vendored packages.
+func TestCleanupLockFormats(t *testing.T) {
+	tree := []FileInfo{
+		{path: "host01/org01/repo01/README", isDir: false},
+		{path: "host01/org01/repo01/file01.go", isDir: false},
+		{path: "host01/org01/repo01/file01_test.go", isDir: false},
+	}
+	expectedFiles := []FileInfo{
+		{path: "host01", isDir: true},
+		{path: "host01/org01", isDir: true},
+		{path: "host01/org01/repo01", isDir: true},
+		{path: "host01/org01/repo01/file01.go", isDir: false},
+		{path: "host01/org01/repo01/file01_test.go", isDir: false},
+	}
+
+	tests := []testData{
+		{
+			// dep's Gopkg.lock, TOML-encoded.
+			tree:       tree,
+			lockFormat: "dep",
+			lockdata: `
+[[projects]]
+  name = "host01/org01/repo01"
+  packages = ["."]
+  revision = "76626ae9c91c4f2a10f34cad8ce83ea42c93bb75"
+`,
+			opts:          options{onlyGo: true},
+			expectedFiles: expectedFiles,
+		},
+		{
+			// go mod vendor's vendor/modules.txt.
+			tree:       tree,
+			lockFormat: "mod",
+			lockdata: `# host01/org01/repo01 v0.0.0-20200101000000-76626ae9c91c
+## explicit
+host01/org01/repo01
+`,
+			opts: options{onlyGo: true},
+			expectedFiles: append([]FileInfo{
+				{path: "modules.txt", isDir: false},
+			}, expectedFiles...),
+		},
+	}
+
+	for i, td := range tests {
+		t.Logf("Test #%d", i)
+		if err := testCleanup(t, &td); err != nil {
+			t.Fatalf("#%d: unexpected error: %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// PackageSpec is a single vendored package recorded in a dependency lock
+// file: its import path and any nested packages beneath it that are also
+// vendored, relative to ImportPath. It's the common shape every LockReader
+// implementation normalizes its ecosystem's lock format into.
+type PackageSpec struct {
+	ImportPath  string
+	Subpackages []string
+}
+
+// packageRoots returns the vendor-relative import paths for the package
+// itself and each of its subpackages.
+func (p PackageSpec) packageRoots() []string {
+	roots := []string{p.ImportPath}
+	for _, sub := range p.Subpackages {
+		roots = append(roots, filepath.Join(p.ImportPath, sub))
+	}
+	return roots
+}
+
+// LockReader is a dependency lock file parsed into the package list cleanup
+// needs to walk vendor/. glide.lock, Gopkg.lock, and vendor/modules.txt each
+// get their own implementation so cleanup can prune any of the three
+// ecosystems' vendor trees.
+type LockReader interface {
+	Packages() []PackageSpec
+}
+
+// readLockFile returns a LockReader over dir's dependency lock file. format
+// forces a specific ecosystem ("glide", "dep", or "mod"); "" or "auto" picks
+// whichever lock file is present.
+func readLockFile(dir, format string) (LockReader, error) {
+	switch format {
+	case "", "auto":
+		return detectLockFile(dir)
+	case "glide":
+		return readGlideLock(dir)
+	case "dep":
+		return readGopkgLock(dir)
+	case "mod":
+		return readModulesTxt(dir)
+	default:
+		return nil, fmt.Errorf("unknown -lock-format %q", format)
+	}
+}
+
+// detectLockFile picks a LockReader based on which lock file exists under
+// dir, preferring glide.lock, then Gopkg.lock, then vendor/modules.txt.
+func detectLockFile(dir string) (LockReader, error) {
+	if fileExists(filepath.Join(dir, "glide.lock")) {
+		return readGlideLock(dir)
+	}
+	if fileExists(filepath.Join(dir, "Gopkg.lock")) {
+		return readGopkgLock(dir)
+	}
+	if fileExists(filepath.Join(dir, "vendor", "modules.txt")) {
+		return readModulesTxt(dir)
+	}
+	return nil, fmt.Errorf("no glide.lock, Gopkg.lock, or vendor/modules.txt found in %s", dir)
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
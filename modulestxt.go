@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// modulesTxtLock is the package list recorded in vendor/modules.txt, as
+// written by `go mod vendor` for a module that vendors its dependencies.
+type modulesTxtLock struct {
+	modules []PackageSpec
+}
+
+// Packages implements LockReader.
+func (l modulesTxtLock) Packages() []PackageSpec {
+	return l.modules
+}
+
+// readModulesTxt parses dir's vendor/modules.txt. Each "# <module> <version>"
+// line starts a new module; the import paths listed under it (one per line,
+// up to the next "# " line) become its PackageSpec, with the module's own
+// path recorded as the root and everything else as a subpackage. "##"
+// directive lines such as "## explicit" carry no package path and are
+// skipped.
+func readModulesTxt(dir string) (modulesTxtLock, error) {
+	f, err := os.Open(filepath.Join(dir, "vendor", "modules.txt"))
+	if err != nil {
+		return modulesTxtLock{}, fmt.Errorf("failed to read vendor/modules.txt: %v", err)
+	}
+	defer f.Close()
+
+	var lock modulesTxtLock
+	var current *PackageSpec
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "## "):
+			// directive, e.g. "## explicit"
+		case strings.HasPrefix(line, "# "):
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				continue
+			}
+			lock.modules = append(lock.modules, PackageSpec{ImportPath: fields[1]})
+			current = &lock.modules[len(lock.modules)-1]
+		case current != nil && strings.TrimSpace(line) != "":
+			pkg := strings.TrimSpace(line)
+			if pkg == current.ImportPath {
+				continue
+			}
+			if sub := strings.TrimPrefix(pkg, current.ImportPath+"/"); sub != pkg {
+				current.Subpackages = append(current.Subpackages, sub)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return modulesTxtLock{}, fmt.Errorf("failed to read vendor/modules.txt: %v", err)
+	}
+	return lock, nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// prunePlan is the set of filesystem changes cleanup would make to a
+// vendor/ tree: directories to remove wholesale (nested vendor trees and
+// unused packages) and individual files matched by the prune mask.
+// cleanupRoots lists the vendored package directories that should be swept
+// for directories left empty by those removals. vendorDir is recorded so
+// applying the plan can also sweep for symlinks left dangling by it.
+type prunePlan struct {
+	vendorDir    string
+	removeDirs   []string
+	removeFiles  []string
+	cleanupRoots []string
+}
+
+// readGlideLock reads and parses dir's glide.lock.
+func readGlideLock(dir string) (glideLock, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "glide.lock"))
+	if err != nil {
+		return glideLock{}, fmt.Errorf("failed to read glide.lock: %v", err)
+	}
+
+	var lock glideLock
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return glideLock{}, fmt.Errorf("failed to parse glide.lock: %v", err)
+	}
+	return lock, nil
+}
+
+// computePrunePlan reads dir's dependency lock file (glide.lock, Gopkg.lock,
+// or vendor/modules.txt, per opts.lockFormat) and glide.yaml, and computes
+// what cleanup would remove from vendor/, without touching disk.
+func computePrunePlan(dir string, opts options) (prunePlan, error) {
+	lock, err := readLockFile(dir, opts.lockFormat)
+	if err != nil {
+		return prunePlan{}, err
+	}
+
+	cfg, err := readPruneConfig(dir)
+	if err != nil {
+		return prunePlan{}, err
+	}
+
+	vendorDir := filepath.Join(dir, "vendor")
+	plan := prunePlan{vendorDir: vendorDir}
+	for _, pkg := range lock.Packages() {
+		popts := resolvePruneOptions(cfg, opts, pkg.ImportPath)
+
+		var skipDirs []string
+		if popts.UnusedPackages {
+			unused, err := planUnusedPackages(vendorDir, pkg.ImportPath, pkg.packageRoots())
+			if err != nil {
+				return prunePlan{}, err
+			}
+			plan.removeDirs = append(plan.removeDirs, unused...)
+			skipDirs = unused
+		}
+
+		// Subpackages are always subdirectories of ImportPath, so walking
+		// the project root once already covers them; walking each
+		// subpackage root too would queue their files for removal twice.
+		pkgDir := filepath.Join(vendorDir, filepath.FromSlash(pkg.ImportPath))
+		if _, err := os.Stat(pkgDir); os.IsNotExist(err) {
+			continue
+		}
+
+		dirs, files, err := planPackage(pkgDir, popts, skipDirs)
+		if err != nil {
+			return prunePlan{}, err
+		}
+		plan.removeDirs = append(plan.removeDirs, dirs...)
+		plan.removeFiles = append(plan.removeFiles, files...)
+		plan.cleanupRoots = append(plan.cleanupRoots, pkgDir)
+	}
+	return plan, nil
+}
+
+// planPackage walks pkgDir and reports which nested vendor/ directories and
+// individual files popts would remove. skipDirs are directories already
+// claimed for whole-tree removal (e.g. by planUnusedPackages); planPackage
+// doesn't recurse into them so their files aren't also queued individually.
+func planPackage(pkgDir string, popts PruneOptions, skipDirs []string) (dirs, files []string, err error) {
+	skip := make(map[string]bool, len(skipDirs))
+	for _, d := range skipDirs {
+		skip[d] = true
+	}
+
+	err = filepath.Walk(pkgDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != pkgDir && info.Name() == "vendor" {
+				dirs = append(dirs, path)
+				return filepath.SkipDir
+			}
+			if skip[path] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(pkgDir, path)
+		if err != nil {
+			return err
+		}
+		if shouldRemove(filepath.ToSlash(rel), info, popts) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return dirs, files, err
+}
+
+// applyPrunePlan performs the removals described by plan.
+func applyPrunePlan(plan prunePlan) error {
+	for _, path := range plan.removeDirs {
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to remove %s: %v", path, err)
+		}
+	}
+	for _, path := range plan.removeFiles {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove %s: %v", path, err)
+		}
+	}
+	for _, root := range plan.cleanupRoots {
+		if _, err := os.Stat(root); os.IsNotExist(err) {
+			continue
+		}
+		if err := removeEmptyDirs(root); err != nil {
+			return err
+		}
+	}
+
+	if plan.vendorDir != "" {
+		if err := pruneDanglingSymlinks(plan.vendorDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
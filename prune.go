@@ -0,0 +1,197 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// PruneOptions is a fully-resolved set of pruning behaviors for a single
+// vendored project, modeled on dep's PruneOptions.
+type PruneOptions struct {
+	UnusedPackages  bool // delete vendored subpackages not listed in the lock file
+	NonGoFiles      bool // delete files that aren't *.go (and aren't licenses)
+	GoTests         bool // delete *_test.go files
+	NonLicenseFiles bool // delete recognized license files too
+
+	// Keep and Exclude are doublestar-style glob patterns evaluated
+	// relative to the package root. A path matching Keep is retained even
+	// when the rules above would remove it; a path matching Exclude is
+	// removed even when it would otherwise be kept. Exclude wins over
+	// Keep. Unlike the bool fields, patterns accumulate across CLI flags,
+	// the prune stanza's default, and any per-project override.
+	Keep    []string
+	Exclude []string
+}
+
+// pruneMask is the YAML shape of a (possibly partial) prune override: a
+// nil bool field means "inherit", letting a project override a single
+// behavior without repeating the rest of the mask. Keep and Exclude
+// always accumulate rather than override.
+type pruneMask struct {
+	UnusedPackages  *bool    `yaml:"unused-packages,omitempty"`
+	NonGoFiles      *bool    `yaml:"non-go-files,omitempty"`
+	GoTests         *bool    `yaml:"go-tests,omitempty"`
+	NonLicenseFiles *bool    `yaml:"non-license-files,omitempty"`
+	Keep            []string `yaml:"keep,omitempty"`
+	Exclude         []string `yaml:"exclude,omitempty"`
+}
+
+// applyTo layers m on top of base: bool fields override only when m sets
+// them, Keep and Exclude patterns are appended.
+func (m pruneMask) applyTo(base PruneOptions) PruneOptions {
+	if m.UnusedPackages != nil {
+		base.UnusedPackages = *m.UnusedPackages
+	}
+	if m.NonGoFiles != nil {
+		base.NonGoFiles = *m.NonGoFiles
+	}
+	if m.GoTests != nil {
+		base.GoTests = *m.GoTests
+	}
+	if m.NonLicenseFiles != nil {
+		base.NonLicenseFiles = *m.NonLicenseFiles
+	}
+	base.Keep = append(base.Keep, m.Keep...)
+	base.Exclude = append(base.Exclude, m.Exclude...)
+	return base
+}
+
+// pruneProject is a per-import-path override from the prune stanza's
+// project list.
+type pruneProject struct {
+	Name      string `yaml:"name"`
+	pruneMask `yaml:",inline"`
+}
+
+// pruneConfig is the `prune:` stanza in glide.yaml: a project-wide default
+// mask plus per-project overrides keyed by import path.
+type pruneConfig struct {
+	Default  pruneMask      `yaml:"default"`
+	Projects []pruneProject `yaml:"projects"`
+}
+
+// glideYAML is the subset of glide.yaml that cleanup reads.
+type glideYAML struct {
+	Prune pruneConfig `yaml:"prune"`
+}
+
+// readPruneConfig reads the prune stanza from dir's glide.yaml. A missing
+// glide.yaml or a glide.yaml without a prune stanza yields a zero-value
+// pruneConfig, so every project falls back to the CLI flags.
+func readPruneConfig(dir string) (pruneConfig, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "glide.yaml"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return pruneConfig{}, nil
+		}
+		return pruneConfig{}, fmt.Errorf("failed to read glide.yaml: %v", err)
+	}
+
+	var y glideYAML
+	if err := yaml.Unmarshal(data, &y); err != nil {
+		return pruneConfig{}, fmt.Errorf("failed to parse glide.yaml: %v", err)
+	}
+	return y.Prune, nil
+}
+
+// cliPruneOptions translates the legacy -only-code/-no-tests flags, plus
+// any -keep/-exclude globs, into a PruneOptions mask. -only-code preserves
+// its historical behavior of stripping non-Go files including license
+// files. Keep/Exclude are copied so later accumulation via applyTo never
+// mutates the CLI-supplied slices.
+func cliPruneOptions(opts options) PruneOptions {
+	return PruneOptions{
+		NonGoFiles:      opts.onlyGo,
+		NonLicenseFiles: opts.onlyGo,
+		GoTests:         opts.noTests,
+		Keep:            append([]string(nil), opts.keep...),
+		Exclude:         append([]string(nil), opts.exclude...),
+	}
+}
+
+// resolvePruneOptions computes the effective PruneOptions for importPath,
+// applying overrides from least to most specific: CLI flags, then the
+// prune stanza's project-wide default, then its per-project override.
+func resolvePruneOptions(cfg pruneConfig, opts options, importPath string) PruneOptions {
+	effective := cfg.Default.applyTo(cliPruneOptions(opts))
+	for _, p := range cfg.Projects {
+		if p.Name == importPath {
+			effective = p.pruneMask.applyTo(effective)
+			break
+		}
+	}
+	return effective
+}
+
+// isLicenseFile reports whether name is a well-known license/legal file.
+func isLicenseFile(name string) bool {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	switch strings.ToUpper(base) {
+	case "LICENSE", "LICENSE-MIT", "LICENSE-APACHE", "COPYING", "NOTICE", "PATENTS":
+		return true
+	}
+	return false
+}
+
+// planUnusedPackages reports the subpackage directories of importPath that
+// contain Go files but aren't among declaredRoots (vendor-relative import
+// paths recorded in the lock file), without touching disk.
+func planUnusedPackages(vendorDir, importPath string, declaredRoots []string) ([]string, error) {
+	declared := make(map[string]bool, len(declaredRoots))
+	for _, root := range declaredRoots {
+		declared[filepath.ToSlash(root)] = true
+	}
+
+	projectDir := filepath.Join(vendorDir, filepath.FromSlash(importPath))
+	var unused []string
+	err := filepath.Walk(projectDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() || path == projectDir {
+			return nil
+		}
+		if info.Name() == "vendor" {
+			return filepath.SkipDir
+		}
+
+		rel, err := filepath.Rel(vendorDir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if declared[rel] {
+			return nil
+		}
+
+		hasGo, err := dirHasGoFiles(path)
+		if err != nil {
+			return err
+		}
+		if hasGo {
+			unused = append(unused, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return unused, err
+}
+
+// dirHasGoFiles reports whether dir directly contains any *.go file.
+func dirHasGoFiles(dir string) (bool, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".go") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// fsState is a snapshot of a directory tree, modeled on dep's
+// filesystemState: the directories, regular files, and symlinks it
+// contains, as paths relative to root.
+type fsState struct {
+	root  string
+	dirs  []string
+	files []string
+	links []fsLink
+}
+
+// fsLink is a symlink found while walking a tree, recorded by its
+// tree-relative path and raw link target.
+type fsLink struct {
+	path   string
+	target string
+}
+
+// computeVendorState walks vendorDir and returns its current state.
+// Symlinks are recorded but never followed.
+func computeVendorState(vendorDir string) (fsState, error) {
+	state := fsState{root: vendorDir}
+	if _, err := os.Stat(vendorDir); os.IsNotExist(err) {
+		return state, nil
+	}
+
+	err := filepath.Walk(vendorDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == vendorDir {
+			return nil
+		}
+
+		rel := relTo(vendorDir, path)
+		if info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			state.links = append(state.links, fsLink{path: rel, target: target})
+			return nil
+		}
+		if info.IsDir() {
+			state.dirs = append(state.dirs, rel)
+			return nil
+		}
+		state.files = append(state.files, rel)
+		return nil
+	})
+	if err != nil {
+		return fsState{}, err
+	}
+
+	sort.Strings(state.dirs)
+	sort.Strings(state.files)
+	sort.Slice(state.links, func(i, j int) bool { return state.links[i].path < state.links[j].path })
+	return state, nil
+}
+
+// afterPlan returns the fsState that would remain once plan's removals are
+// applied to state, including the empty directories and dangling symlinks
+// that applyPrunePlan sweeps afterwards.
+func (state fsState) afterPlan(plan prunePlan) fsState {
+	isDir := make(map[string]bool, len(state.dirs))
+	linkTarget := make(map[string]string, len(state.links))
+	present := make(map[string]bool, len(state.dirs)+len(state.files)+len(state.links))
+
+	for _, d := range state.dirs {
+		isDir[d] = true
+		present[d] = true
+	}
+	for _, f := range state.files {
+		present[f] = true
+	}
+	for _, l := range state.links {
+		linkTarget[l.path] = l.target
+		present[l.path] = true
+	}
+
+	removeSubtree := func(path string) {
+		prefix := path + "/"
+		for p := range present {
+			if p == path || strings.HasPrefix(p, prefix) {
+				delete(present, p)
+			}
+		}
+	}
+	for _, d := range plan.removeDirs {
+		removeSubtree(relTo(state.root, d))
+	}
+	for _, f := range plan.removeFiles {
+		delete(present, relTo(state.root, f))
+	}
+
+	// As removeEmptyDirs does for each of plan.cleanupRoots, a directory
+	// below a root that is left with no children is pruned (which may empty
+	// its own parent); the roots themselves and directories outside them
+	// are kept. Then, as pruneDanglingSymlinks does, a symlink whose target
+	// no longer exists inside vendor/ is pruned, in one pass.
+	var roots []string
+	for _, r := range plan.cleanupRoots {
+		roots = append(roots, relTo(state.root, r)+"/")
+	}
+	for changed := true; changed; {
+		changed = false
+		for d := range isDir {
+			if !present[d] || hasChild(present, d) {
+				continue
+			}
+			for _, r := range roots {
+				if strings.HasPrefix(d, r) {
+					delete(present, d)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	for _, l := range state.links {
+		if !present[l.path] {
+			continue
+		}
+		relTarget, ok := resolveLinkTarget(state.root, l.path, l.target)
+		if ok && !present[relTarget] {
+			delete(present, l.path)
+		}
+	}
+
+	desired := fsState{root: state.root}
+	for p := range present {
+		switch {
+		case isDir[p]:
+			desired.dirs = append(desired.dirs, p)
+		case linkTarget[p] != "":
+			desired.links = append(desired.links, fsLink{path: p, target: linkTarget[p]})
+		default:
+			desired.files = append(desired.files, p)
+		}
+	}
+	sort.Strings(desired.dirs)
+	sort.Strings(desired.files)
+	sort.Slice(desired.links, func(i, j int) bool { return desired.links[i].path < desired.links[j].path })
+	return desired
+}
+
+// hasChild reports whether present contains any path nested under dir.
+func hasChild(present map[string]bool, dir string) bool {
+	prefix := dir + "/"
+	for p := range present {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// relTo returns path relative to root as a slash-separated string.
+func relTo(root, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// vendorDiff is the set of path-level changes between two fsStates.
+type vendorDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Kept    []string `json:"kept"`
+}
+
+// diffStates compares current against desired and classifies every path
+// seen in either as added, removed, or kept.
+func diffStates(current, desired fsState) vendorDiff {
+	curSet := pathSet(current)
+	desSet := pathSet(desired)
+
+	var diff vendorDiff
+	for p := range curSet {
+		if desSet[p] {
+			diff.Kept = append(diff.Kept, p)
+		} else {
+			diff.Removed = append(diff.Removed, p)
+		}
+	}
+	for p := range desSet {
+		if !curSet[p] {
+			diff.Added = append(diff.Added, p)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Kept)
+	return diff
+}
+
+// pathSet flattens a fsState's dirs, files, and links into one set.
+func pathSet(state fsState) map[string]bool {
+	set := make(map[string]bool, len(state.dirs)+len(state.files)+len(state.links))
+	for _, d := range state.dirs {
+		set[d] = true
+	}
+	for _, f := range state.files {
+		set[f] = true
+	}
+	for _, l := range state.links {
+		set[l.path] = true
+	}
+	return set
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// resolveLinkTarget resolves a symlink's raw target (as returned by
+// os.Readlink) against linkRelPath, the symlink's own path relative to
+// vendorDir. ok is false when the target is absolute or escapes vendorDir,
+// in which case dangling-symlink pruning doesn't apply to it — only
+// intra-vendor links are pruned when their target disappears.
+func resolveLinkTarget(vendorDir, linkRelPath, rawTarget string) (relTarget string, ok bool) {
+	if filepath.IsAbs(rawTarget) {
+		return "", false
+	}
+
+	linkDir := filepath.Dir(filepath.FromSlash(linkRelPath))
+	abs := filepath.Join(vendorDir, linkDir, filepath.FromSlash(rawTarget))
+	rel, err := filepath.Rel(vendorDir, abs)
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", false
+	}
+	return rel, true
+}
+
+// pruneDanglingSymlinks removes symlinks under vendorDir whose target no
+// longer exists inside vendor/, which happens once cleanup has removed the
+// file or directory they pointed at.
+func pruneDanglingSymlinks(vendorDir string) error {
+	var links []string
+	err := filepath.Walk(vendorDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			links = append(links, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, link := range links {
+		rawTarget, err := os.Readlink(link)
+		if err != nil {
+			return err
+		}
+
+		relTarget, ok := resolveLinkTarget(vendorDir, relTo(vendorDir, link), rawTarget)
+		if !ok {
+			continue
+		}
+
+		if _, err := os.Lstat(filepath.Join(vendorDir, filepath.FromSlash(relTarget))); os.IsNotExist(err) {
+			if err := os.Remove(link); err != nil {
+				return fmt.Errorf("failed to remove dangling symlink %s: %v", link, err)
+			}
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// verify computes the prune plan for dir and diffs the current vendor/
+// tree against the tree cleanup would leave behind, without touching disk.
+func verify(dir string, opts options) (vendorDiff, error) {
+	plan, err := computePrunePlan(dir, opts)
+	if err != nil {
+		return vendorDiff{}, err
+	}
+
+	vendorDir := filepath.Join(dir, "vendor")
+	current, err := computeVendorState(vendorDir)
+	if err != nil {
+		return vendorDiff{}, err
+	}
+
+	desired := current.afterPlan(plan)
+	return diffStates(current, desired), nil
+}
+
+// printDiff writes diff to w as either plain text (one path per line,
+// prefixed with "-" for removed and "+" for added) or JSON.
+func printDiff(w io.Writer, diff vendorDiff, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(diff)
+	case "", "text":
+		for _, p := range diff.Removed {
+			fmt.Fprintf(w, "- %s\n", p)
+		}
+		for _, p := range diff.Added {
+			fmt.Fprintf(w, "+ %s\n", p)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}